@@ -0,0 +1,104 @@
+package render
+
+import (
+	"fmt"
+	"path"
+)
+
+// AssetFile is a single rendered file produced by an Asset.
+type AssetFile struct {
+	Name string
+	Data []byte
+}
+
+// Asset is a node in the manifest generation graph. Each Asset knows how to
+// produce its own output given its already-generated dependencies, which
+// lets the Store resolve only the assets a caller actually needs instead of
+// running the entire manifest pipeline.
+type Asset interface {
+	// Name uniquely identifies the asset within a Store.
+	Name() string
+	// Dependencies lists the assets that must be generated before this one.
+	Dependencies() []Asset
+	// Generate produces the asset's output. parents contains the generated
+	// form of every asset returned by Dependencies, keyed by Name.
+	Generate(parents map[string]Asset) error
+	// Files returns the output produced by the most recent Generate call.
+	Files() []AssetFile
+}
+
+// Store topologically resolves a set of Assets, generating each one at most
+// once and reusing the cached result for any asset shared by more than one
+// dependent.
+type Store struct {
+	generated map[string]Asset
+}
+
+// NewStore returns an empty Store.
+func NewStore() *Store {
+	return &Store{generated: make(map[string]Asset)}
+}
+
+// Resolve generates the given assets and all of their transitive
+// dependencies, in dependency order.
+func (s *Store) Resolve(assets ...Asset) error {
+	for _, a := range assets {
+		if err := s.resolve(a, map[string]bool{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) resolve(a Asset, visiting map[string]bool) error {
+	if _, ok := s.generated[a.Name()]; ok {
+		return nil
+	}
+	if visiting[a.Name()] {
+		return fmt.Errorf("cycle detected resolving asset %q", a.Name())
+	}
+	visiting[a.Name()] = true
+
+	parents := make(map[string]Asset)
+	for _, dep := range a.Dependencies() {
+		if err := s.resolve(dep, visiting); err != nil {
+			return err
+		}
+		parents[dep.Name()] = s.generated[dep.Name()]
+	}
+
+	if err := a.Generate(parents); err != nil {
+		return fmt.Errorf("failed to generate asset %q: %w", a.Name(), err)
+	}
+	s.generated[a.Name()] = a
+	delete(visiting, a.Name())
+	return nil
+}
+
+// Files returns the combined output of the given assets and their
+// dependencies, which must already have been resolved via Resolve. Each
+// asset's files appear once, in dependency order.
+func (s *Store) Files(assets ...Asset) []AssetFile {
+	var files []AssetFile
+	seen := make(map[string]bool)
+	var walk func(Asset)
+	walk = func(a Asset) {
+		if seen[a.Name()] {
+			return
+		}
+		seen[a.Name()] = true
+		for _, dep := range a.Dependencies() {
+			walk(dep)
+		}
+		files = append(files, a.Files()...)
+	}
+	for _, a := range assets {
+		walk(a)
+	}
+	return files
+}
+
+// assetFileName turns a manifest template path into its output file name.
+func assetFileName(templatePath string) string {
+	return path.Base(templatePath)
+}
@@ -0,0 +1,113 @@
+package render
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// fakeAsset is a minimal Asset for exercising Store without the rest of the
+// render package's template machinery.
+type fakeAsset struct {
+	name     string
+	deps     []Asset
+	files    []AssetFile
+	generate func(parents map[string]Asset) error
+	calls    *[]string
+}
+
+func (a *fakeAsset) Name() string          { return a.name }
+func (a *fakeAsset) Dependencies() []Asset { return a.deps }
+func (a *fakeAsset) Files() []AssetFile    { return a.files }
+func (a *fakeAsset) Generate(parents map[string]Asset) error {
+	if a.calls != nil {
+		*a.calls = append(*a.calls, a.name)
+	}
+	if a.generate != nil {
+		return a.generate(parents)
+	}
+	return nil
+}
+
+func TestStoreResolveOrdersDependenciesBeforeDependents(t *testing.T) {
+	var calls []string
+	base := &fakeAsset{name: "base", calls: &calls}
+	mid := &fakeAsset{name: "mid", deps: []Asset{base}, calls: &calls}
+	top := &fakeAsset{name: "top", deps: []Asset{mid}, calls: &calls}
+
+	store := NewStore()
+	if err := store.Resolve(top); err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+
+	want := []string{"base", "mid", "top"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Generate call order = %v, want %v", calls, want)
+	}
+}
+
+func TestStoreResolveGeneratesSharedDependencyOnce(t *testing.T) {
+	var calls []string
+	shared := &fakeAsset{name: "shared", calls: &calls}
+	left := &fakeAsset{name: "left", deps: []Asset{shared}, calls: &calls}
+	right := &fakeAsset{name: "right", deps: []Asset{shared}, calls: &calls}
+
+	store := NewStore()
+	if err := store.Resolve(left, right); err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+
+	count := 0
+	for _, name := range calls {
+		if name == "shared" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("shared asset generated %d times, want 1", count)
+	}
+}
+
+func TestStoreResolveDetectsCycle(t *testing.T) {
+	a := &fakeAsset{name: "a"}
+	b := &fakeAsset{name: "b"}
+	a.deps = []Asset{b}
+	b.deps = []Asset{a}
+
+	store := NewStore()
+	if err := store.Resolve(a); err == nil {
+		t.Fatal("Resolve returned nil error for a cyclic asset graph")
+	}
+}
+
+func TestStoreResolvePropagatesGenerateError(t *testing.T) {
+	wantErr := fmt.Errorf("boom")
+	a := &fakeAsset{name: "a", generate: func(map[string]Asset) error { return wantErr }}
+
+	store := NewStore()
+	err := store.Resolve(a)
+	if err == nil {
+		t.Fatal("Resolve returned nil error, want wrapped generate error")
+	}
+}
+
+func TestStoreFilesDedupesAndOrdersByDependency(t *testing.T) {
+	shared := &fakeAsset{name: "shared", files: []AssetFile{{Name: "shared.yaml"}}}
+	left := &fakeAsset{name: "left", deps: []Asset{shared}, files: []AssetFile{{Name: "left.yaml"}}}
+	right := &fakeAsset{name: "right", deps: []Asset{shared}, files: []AssetFile{{Name: "right.yaml"}}}
+
+	store := NewStore()
+	if err := store.Resolve(left, right); err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+
+	files := store.Files(left, right)
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	want := []string{"shared.yaml", "left.yaml", "right.yaml"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Files() = %v, want %v", names, want)
+	}
+}
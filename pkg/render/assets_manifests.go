@@ -0,0 +1,270 @@
+package render
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	assets "github.com/openshift-hive/hypershift-installer/pkg/assets"
+)
+
+// manifestAsset is the common implementation shared by the manifest group
+// assets below: it templates a fixed list of embedded manifest files against
+// the cluster render context and exposes the result as AssetFiles.
+type manifestAsset struct {
+	name         string
+	ctx          *clusterManifestContext
+	templates    []string
+	dependencies []Asset
+	files        []AssetFile
+}
+
+func (a *manifestAsset) Name() string          { return a.name }
+func (a *manifestAsset) Dependencies() []Asset { return a.dependencies }
+func (a *manifestAsset) Files() []AssetFile    { return a.files }
+
+func (a *manifestAsset) Generate(parents map[string]Asset) error {
+	for _, t := range a.templates {
+		data, err := a.ctx.substituteParams(a.ctx.params, t)
+		if err != nil {
+			return err
+		}
+		a.files = append(a.files, AssetFile{Name: assetFileName(t), Data: []byte(data)})
+	}
+	return nil
+}
+
+// userManifestAsset registers a fixed list of manifest templates with the
+// context's userManifestFiles so that userManifestsBootstrapperAsset wraps
+// them as ConfigMaps; it contributes no top-level Files of its own.
+type userManifestAsset struct {
+	name      string
+	ctx       *clusterManifestContext
+	templates []string
+}
+
+func (a *userManifestAsset) Name() string          { return a.name }
+func (a *userManifestAsset) Dependencies() []Asset { return nil }
+func (a *userManifestAsset) Files() []AssetFile    { return nil }
+
+func (a *userManifestAsset) Generate(parents map[string]Asset) error {
+	a.ctx.addUserManifestFiles(a.templates...)
+	return nil
+}
+
+func newEtcdAsset(ctx *clusterManifestContext) Asset {
+	return &manifestAsset{
+		name: "Etcd",
+		ctx:  ctx,
+		templates: []string{
+			"etcd/etcd-cluster-crd.yaml",
+			"etcd/etcd-cluster.yaml",
+			"etcd/etcd-operator-cluster-role-binding.yaml",
+			"etcd/etcd-operator-cluster-role.yaml",
+			"etcd/etcd-operator.yaml",
+		},
+	}
+}
+
+// kubeAPIServerAsset templates the kube-apiserver manifests and applies the
+// deployment patch that wires in whichever components were enabled.
+type kubeAPIServerAsset struct {
+	manifestAsset
+}
+
+func newKubeAPIServerAsset(ctx *clusterManifestContext, tunnel TunnelProvider) Asset {
+	var templates []string
+	if tunnel != nil {
+		templates = append(templates, tunnel.KubeAPIServerTemplates()...)
+	}
+	return &kubeAPIServerAsset{manifestAsset{
+		name:      "KubeAPIServer",
+		ctx:       ctx,
+		templates: templates,
+	}}
+}
+
+func (a *kubeAPIServerAsset) Generate(parents map[string]Asset) error {
+	if err := a.manifestAsset.Generate(parents); err != nil {
+		return err
+	}
+	patched, err := a.patchDeployment()
+	if err != nil {
+		return err
+	}
+	a.files = append(a.files, patched)
+	return nil
+}
+
+// patchDeployment merges kube-apiserver-deployment-patch.yaml onto
+// kube-apiserver-deployment.yaml with mergeUnstructured (maps are merged
+// recursively; a list in the patch replaces the base list) and returns the
+// result as an AssetFile, so
+// it flows through runTransformers like every other manifest instead of
+// bypassing the pipeline via a direct write to the render context.
+func (a *kubeAPIServerAsset) patchDeployment() (AssetFile, error) {
+	const deploymentTemplate = "kube-apiserver/kube-apiserver-deployment.yaml"
+	const patchTemplate = "kube-apiserver/kube-apiserver-deployment-patch.yaml"
+
+	base, err := a.ctx.substituteParams(a.ctx.params, deploymentTemplate)
+	if err != nil {
+		return AssetFile{}, err
+	}
+	patch, err := a.ctx.substituteParams(a.ctx.params, patchTemplate)
+	if err != nil {
+		return AssetFile{}, err
+	}
+
+	var baseObj, patchObj map[string]interface{}
+	if err := yaml.Unmarshal([]byte(base), &baseObj); err != nil {
+		return AssetFile{}, fmt.Errorf("parsing %s: %w", deploymentTemplate, err)
+	}
+	if err := yaml.Unmarshal([]byte(patch), &patchObj); err != nil {
+		return AssetFile{}, fmt.Errorf("parsing %s: %w", patchTemplate, err)
+	}
+	if err := mergeUnstructured(baseObj, patchObj); err != nil {
+		return AssetFile{}, fmt.Errorf("applying %s: %w", patchTemplate, err)
+	}
+
+	data, err := yaml.Marshal(baseObj)
+	if err != nil {
+		return AssetFile{}, fmt.Errorf("marshaling %s: %w", deploymentTemplate, err)
+	}
+	return AssetFile{Name: assetFileName(deploymentTemplate), Data: data}, nil
+}
+
+// openVPNAsset templates the server-side OpenVPN manifests and pulls in the
+// client-side manifests as a user-manifest dependency.
+type openVPNAsset struct {
+	manifestAsset
+}
+
+func newOpenVPNAsset(ctx *clusterManifestContext, restrictedSCC bool) Asset {
+	client := &userManifestAsset{
+		name: "OpenVPNClient",
+		ctx:  ctx,
+		templates: []string{
+			"openvpn/openvpn-client-deployment.yaml",
+			"openvpn/openvpn-client-configmap.yaml",
+		},
+	}
+	templates := []string{
+		"openvpn/openvpn-serviceaccount.yaml",
+		"openvpn/openvpn-server-deployment.yaml",
+		"openvpn/openvpn-ccd-configmap.yaml",
+		"openvpn/openvpn-server-configmap.yaml",
+	}
+	if !restrictedSCC {
+		templates = append(templates, "openvpn/openvpn-server-scc-rolebinding.yaml")
+	}
+	return &openVPNAsset{manifestAsset{
+		name:         "OpenVPN",
+		ctx:          ctx,
+		dependencies: []Asset{client},
+		templates:    templates,
+	}}
+}
+
+func newRouterProxyAsset(ctx *clusterManifestContext, restrictedSCC bool) Asset {
+	templates := []string{
+		"router-proxy/router-proxy-deployment.yaml",
+		"router-proxy/router-proxy-configmap.yaml",
+		"router-proxy/router-proxy-vpnclient-configmap.yaml",
+		"router-proxy/router-proxy-http-service.yaml",
+		"router-proxy/router-proxy-https-service.yaml",
+	}
+	if !restrictedSCC {
+		templates = append(templates, "router-proxy/router-proxy-scc-rolebinding.yaml")
+	}
+	return &manifestAsset{
+		name:      "RouterProxy",
+		ctx:       ctx,
+		templates: templates,
+	}
+}
+
+func newHypershiftOperatorAsset(ctx *clusterManifestContext, restrictedSCC bool) Asset {
+	templates := []string{
+		"hypershift-operator/hypershift-operator-deployment.yaml",
+	}
+	if !restrictedSCC {
+		templates = append(templates, "hypershift-operator/hypershift-operator-scc-rolebinding.yaml")
+	}
+	return &manifestAsset{
+		name:      "HypershiftOperator",
+		ctx:       ctx,
+		templates: templates,
+	}
+}
+
+func newRegistryAsset(ctx *clusterManifestContext) Asset {
+	return &userManifestAsset{
+		name:      "Registry",
+		ctx:       ctx,
+		templates: []string{"registry/cluster-imageregistry-config.yaml"},
+	}
+}
+
+func newOauthOpenshiftServerAsset(ctx *clusterManifestContext) Asset {
+	return &userManifestAsset{
+		name:      "OauthOpenshiftServer",
+		ctx:       ctx,
+		templates: []string{"oauth-openshift/ingress-certs-secret.yaml"},
+	}
+}
+
+// clusterBootstrapAsset registers the embedded cluster-bootstrap manifests,
+// which are discovered at runtime rather than listed explicitly, as user
+// manifests.
+type clusterBootstrapAsset struct {
+	userManifestAsset
+}
+
+func newClusterBootstrapAsset(ctx *clusterManifestContext) Asset {
+	return &clusterBootstrapAsset{userManifestAsset{name: "ClusterBootstrap", ctx: ctx}}
+}
+
+func (a *clusterBootstrapAsset) Generate(parents map[string]Asset) error {
+	manifests, err := assets.AssetDir("cluster-bootstrap")
+	if err != nil {
+		return err
+	}
+	for _, m := range manifests {
+		a.templates = append(a.templates, "cluster-bootstrap/"+m)
+	}
+	return a.userManifestAsset.Generate(parents)
+}
+
+// userManifestsBootstrapperAsset templates the bootstrapper pod and wraps
+// every manifest registered by its dependencies into the ConfigMaps that
+// pod consumes.
+type userManifestsBootstrapperAsset struct {
+	manifestAsset
+}
+
+func newUserManifestsBootstrapperAsset(ctx *clusterManifestContext, restrictedSCC bool, deps ...Asset) Asset {
+	templates := []string{
+		"user-manifests-bootstrapper/user-manifests-bootstrapper-pod.yaml",
+	}
+	if !restrictedSCC {
+		templates = append(templates, "user-manifests-bootstrapper/user-manifests-bootstrapper-scc-rolebinding.yaml")
+	}
+	return &userManifestsBootstrapperAsset{manifestAsset{
+		name:         "UserManifestsBootstrapper",
+		ctx:          ctx,
+		dependencies: deps,
+		templates:    templates,
+	}}
+}
+
+func (a *userManifestsBootstrapperAsset) Generate(parents map[string]Asset) error {
+	if err := a.manifestAsset.Generate(parents); err != nil {
+		return err
+	}
+	wrapped, err := a.ctx.wrapUserManifests()
+	if err != nil {
+		return err
+	}
+	a.files = append(a.files, wrapped...)
+	return nil
+}
@@ -0,0 +1,20 @@
+package render
+
+import "reflect"
+
+// defaultFunc mirrors dir2config's `default` template function: it returns
+// value unless value is the zero value for its type, in which case it
+// returns fallback. It lets manifests reference a ClusterParams field that
+// may be unset without a panic or an explicit conditional.
+func defaultFunc() func(fallback, value interface{}) interface{} {
+	return func(fallback, value interface{}) interface{} {
+		if value == nil {
+			return fallback
+		}
+		v := reflect.ValueOf(value)
+		if v.IsZero() {
+			return fallback
+		}
+		return value
+	}
+}
@@ -0,0 +1,63 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GehirnInc/crypt"
+	_ "github.com/GehirnInc/crypt/sha512_crypt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBcryptFuncRoundTrip(t *testing.T) {
+	hash, err := bcryptFunc()("s3cr3t")
+	if err != nil {
+		t.Fatalf("bcryptFunc returned error: %v", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("s3cr3t")); err != nil {
+		t.Errorf("generated hash does not validate against the original password: %v", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("wrong")); err == nil {
+		t.Error("generated hash validated against the wrong password")
+	}
+}
+
+func TestSHA512CryptFuncRoundTrip(t *testing.T) {
+	hash, err := sha512cryptFunc()("s3cr3t")
+	if err != nil {
+		t.Fatalf("sha512cryptFunc returned error: %v", err)
+	}
+	if err := crypt.SHA512.New().Verify(hash, []byte("s3cr3t")); err != nil {
+		t.Errorf("generated hash does not validate against the original password: %v", err)
+	}
+	if err := crypt.SHA512.New().Verify(hash, []byte("wrong")); err == nil {
+		t.Error("generated hash validated against the wrong password")
+	}
+}
+
+func TestHTPasswdEntryLineHashesPlaintextPassword(t *testing.T) {
+	e := HTPasswdEntry{Username: "admin", Password: "s3cr3t"}
+	line, err := e.line()
+	if err != nil {
+		t.Fatalf("line() returned error: %v", err)
+	}
+	prefix := "admin:"
+	if !strings.HasPrefix(line, prefix) {
+		t.Fatalf("line() = %q, want prefix %q", line, prefix)
+	}
+	hash := strings.TrimPrefix(line, prefix)
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("s3cr3t")); err != nil {
+		t.Errorf("hashed line does not validate against the original password: %v", err)
+	}
+}
+
+func TestHTPasswdEntryLinePreservesPrehashedPassword(t *testing.T) {
+	e := HTPasswdEntry{Username: "admin", Password: "already-hashed", Hashed: true}
+	line, err := e.line()
+	if err != nil {
+		t.Fatalf("line() returned error: %v", err)
+	}
+	if want := "admin:already-hashed"; line != want {
+		t.Errorf("line() = %q, want %q", line, want)
+	}
+}
@@ -6,18 +6,37 @@ import (
 	"text/template"
 
 	"github.com/openshift-hive/hypershift-installer/pkg/api"
-	assets "github.com/openshift-hive/hypershift-installer/pkg/assets"
 	"github.com/openshift-hive/hypershift-installer/pkg/release"
 )
 
-// RenderClusterManifests renders manifests for a hosted control plane cluster
-func RenderClusterManifests(params *api.ClusterParams, pullSecretFile, pkiDir, outputDir string, etcd bool, vpn bool, externalOauth bool, includeRegistry bool) error {
+// RenderClusterManifests renders manifests for a hosted control plane
+// cluster. tunnel selects the data-plane-to-control-plane connectivity
+// mechanism; pass nil to omit a tunnel entirely. If
+// params.TransformersConfigFile is set, the manifests are run through the
+// Transformer pipeline it describes before being written to outputDir.
+// htpasswdEntries, if non-empty, renders an htpasswd OAuth identity
+// provider Secret with those users; it is ignored unless externalOauth is
+// also set. restrictedSCC renders the Deployments/Pods of the components
+// listed in securityContextComponents against OpenShift's restricted-v2 SCC
+// instead of requiring cluster-admin-granted privileges, for deployment into
+// multi-tenant clusters; other workloads are left as rendered.
+func RenderClusterManifests(params *api.ClusterParams, pullSecretFile, pkiDir, outputDir string, etcd bool, tunnel TunnelProvider, externalOauth bool, includeRegistry bool, htpasswdEntries []HTPasswdEntry, restrictedSCC bool) error {
 	releaseInfo, err := release.GetReleaseInfo(params.ReleaseImage, params.OriginReleasePrefix, pullSecretFile)
 	if err != nil {
 		return err
 	}
-	ctx := newClusterManifestContext(releaseInfo.Images, releaseInfo.Versions, params, pkiDir, outputDir, vpn)
-	ctx.setupManifests(etcd, vpn, externalOauth, includeRegistry)
+	var transformers []Transformer
+	if params.TransformersConfigFile != "" {
+		transformers, err = LoadTransformerConfig(params.TransformersConfigFile)
+		if err != nil {
+			return err
+		}
+	}
+	ctx := newClusterManifestContext(releaseInfo.Images, releaseInfo.Versions, params, pkiDir, outputDir, tunnel, restrictedSCC)
+	ctx.transformers = transformers
+	if err := ctx.setupManifests(etcd, tunnel, externalOauth, includeRegistry, htpasswdEntries, restrictedSCC); err != nil {
+		return err
+	}
 	return ctx.renderManifests()
 }
 
@@ -25,127 +44,126 @@ type clusterManifestContext struct {
 	*renderContext
 	userManifestFiles []string
 	userManifests     map[string]string
+	transformers      []Transformer
 }
 
-func newClusterManifestContext(images, versions map[string]string, params interface{}, pkiDir, outputDir string, includeVPN bool) *clusterManifestContext {
+func newClusterManifestContext(images, versions map[string]string, params interface{}, pkiDir, outputDir string, tunnel TunnelProvider, restrictedSCC bool) *clusterManifestContext {
 	ctx := &clusterManifestContext{
 		renderContext: newRenderContext(params, outputDir),
 		userManifests: make(map[string]string),
 	}
-	ctx.setFuncs(template.FuncMap{
-		"version":           versionFunc(versions),
-		"imageFor":          imageFunc(images),
-		"base64String":      base64StringEncode,
-		"indent":            indent,
-		"address":           cidrAddress,
-		"mask":              cidrMask,
-		"include":           includeFileFunc(params, ctx.renderContext),
-		"includeVPN":        includeVPNFunc(includeVPN),
-		"randomString":      randomString,
-		"includeData":       includeDataFunc(),
-		"trimTrailingSpace": trimTrailingSpace,
-		"pki":               pkiFunc(pkiDir),
-	})
+	funcs := template.FuncMap{
+		"version":            versionFunc(versions),
+		"imageFor":           imageFunc(images),
+		"base64String":       base64StringEncode,
+		"indent":             indent,
+		"address":            cidrAddress,
+		"mask":               cidrMask,
+		"include":            includeFileFunc(params, ctx.renderContext),
+		"includeVPN":         includeVPNFunc(false),
+		"randomString":       randomString,
+		"includeData":        includeDataFunc(),
+		"trimTrailingSpace":  trimTrailingSpace,
+		"pki":                pkiFunc(pkiDir),
+		"default":            defaultFunc(),
+		"bcrypt":             bcryptFunc(),
+		"sha512crypt":        sha512cryptFunc(),
+		"securityContextFor": securityContextForFunc(restrictedSCC),
+	}
+	if tunnel != nil {
+		for name, fn := range tunnel.FuncMap(ctx) {
+			funcs[name] = fn
+		}
+	}
+	ctx.setFuncs(funcs)
 	return ctx
 }
 
-func (c *clusterManifestContext) setupManifests(etcd bool, vpn bool, externalOauth bool, includeRegistry bool) {
+// setupManifests builds the asset graph for the requested feature set,
+// resolves it through a Store, runs the result through c.transformers, and
+// hands the transformed manifests to the underlying render context.
+func (c *clusterManifestContext) setupManifests(etcd bool, tunnel TunnelProvider, externalOauth bool, includeRegistry bool, htpasswdEntries []HTPasswdEntry, restrictedSCC bool) error {
+	store := NewStore()
+
+	var userManifestDeps []Asset
+	clusterBootstrap := newClusterBootstrapAsset(c)
+	userManifestDeps = append(userManifestDeps, clusterBootstrap)
+
+	roots := []Asset{clusterBootstrap}
+
 	if etcd {
-		c.etcd()
+		roots = append(roots, newEtcdAsset(c))
 	}
-	c.kubeAPIServer()
-	c.clusterBootstrap()
+	roots = append(roots, newKubeAPIServerAsset(c, tunnel))
 	if externalOauth {
-		c.oauthOpenshiftServer()
+		oauth := newOauthOpenshiftServerAsset(c)
+		userManifestDeps = append(userManifestDeps, oauth)
+		roots = append(roots, oauth)
+
+		if htpasswd := newHTPasswdIdentityProviderAsset(c, htpasswdEntries); htpasswd != nil {
+			userManifestDeps = append(userManifestDeps, htpasswd)
+			roots = append(roots, htpasswd)
+		}
 	}
-	if vpn {
-		c.openVPN()
+	if tunnel != nil {
+		tunnelAsset := tunnel.Asset(c, restrictedSCC)
+		userManifestDeps = append(userManifestDeps, tunnelAsset)
+		roots = append(roots, tunnelAsset)
 	}
 	if includeRegistry {
-		c.registry()
+		registry := newRegistryAsset(c)
+		userManifestDeps = append(userManifestDeps, registry)
+		roots = append(roots, registry)
 	}
-	c.userManifestsBootstrapper()
-	c.routerProxy()
-	c.hypershiftOperator()
-}
+	roots = append(roots, newRouterProxyAsset(c, restrictedSCC), newHypershiftOperatorAsset(c, restrictedSCC))
 
-func (c *clusterManifestContext) etcd() {
-	c.addManifestFiles(
-		"etcd/etcd-cluster-crd.yaml",
-		"etcd/etcd-cluster.yaml",
-		"etcd/etcd-operator-cluster-role-binding.yaml",
-		"etcd/etcd-operator-cluster-role.yaml",
-		"etcd/etcd-operator.yaml",
-	)
+	userManifestsBootstrapper := newUserManifestsBootstrapperAsset(c, restrictedSCC, userManifestDeps...)
+	roots = append(roots, userManifestsBootstrapper)
 
-}
-
-func (c *clusterManifestContext) oauthOpenshiftServer() {
-	c.addUserManifestFiles(
-		"oauth-openshift/ingress-certs-secret.yaml",
-	)
-}
-
-func (c *clusterManifestContext) kubeAPIServer() {
-	c.addPatch(
-		"kube-apiserver-deployment.yaml",
-		"kube-apiserver/kube-apiserver-deployment-patch.yaml")
-	c.addManifestFiles(
-		"kube-apiserver/kube-apiserver-vpnclient-config.yaml",
-	)
-}
+	if err := store.Resolve(roots...); err != nil {
+		return err
+	}
 
-func (c *clusterManifestContext) registry() {
-	c.addUserManifestFiles("registry/cluster-imageregistry-config.yaml")
-}
+	// SecurityContextTransformer runs ahead of any user-configured
+	// transformers so that a configured patch can still override the
+	// security context it sets on a per-component basis.
+	c.transformers = append([]Transformer{SecurityContextTransformer(restrictedSCC)}, c.transformers...)
 
-func (c *clusterManifestContext) clusterBootstrap() {
-	manifests, err := assets.AssetDir("cluster-bootstrap")
+	files, err := c.runTransformers(store.Files(roots...))
 	if err != nil {
-		panic(err.Error())
+		return err
 	}
-	for _, m := range manifests {
-		c.addUserManifestFiles("cluster-bootstrap/" + m)
+	for _, f := range files {
+		c.addManifest(f.Name, string(f.Data))
 	}
+	return nil
 }
 
-func (c *clusterManifestContext) openVPN() {
-	c.addManifestFiles(
-		"openvpn/openvpn-serviceaccount.yaml",
-		"openvpn/openvpn-server-deployment.yaml",
-		"openvpn/openvpn-ccd-configmap.yaml",
-		"openvpn/openvpn-server-configmap.yaml",
-	)
-	c.addUserManifestFiles(
-		"openvpn/openvpn-client-deployment.yaml",
-		"openvpn/openvpn-client-configmap.yaml",
-	)
-}
-
-func (c *clusterManifestContext) routerProxy() {
-	c.addManifestFiles(
-		"router-proxy/router-proxy-deployment.yaml",
-		"router-proxy/router-proxy-configmap.yaml",
-		"router-proxy/router-proxy-vpnclient-configmap.yaml",
-		"router-proxy/router-proxy-http-service.yaml",
-		"router-proxy/router-proxy-https-service.yaml",
-	)
+// KubeAPIServerManifests renders only the kube-apiserver manifests for ctx,
+// without running the rest of the manifest pipeline. tunnel contributes its
+// KubeAPIServerTemplates, as in RenderClusterManifests; pass nil for none.
+func (c *clusterManifestContext) KubeAPIServerManifests(tunnel TunnelProvider) ([]AssetFile, error) {
+	store := NewStore()
+	asset := newKubeAPIServerAsset(c, tunnel)
+	if err := store.Resolve(asset); err != nil {
+		return nil, err
+	}
+	return store.Files(asset), nil
 }
 
-func (c *clusterManifestContext) hypershiftOperator() {
-	c.addManifestFiles(
-		"hypershift-operator/hypershift-operator-deployment.yaml",
-	)
-}
+// wrapUserManifests turns every file registered via addUserManifestFiles and
+// every entry of userManifests into an AssetFile carrying the ConfigMap
+// manifest consumed by the user-manifests-bootstrapper pod. The caller is
+// responsible for feeding the result through the same Store/Transformer
+// path as every other AssetFile, rather than writing it to the render
+// context directly.
+func (c *clusterManifestContext) wrapUserManifests() ([]AssetFile, error) {
+	var files []AssetFile
 
-func (c *clusterManifestContext) userManifestsBootstrapper() {
-	c.addManifestFiles(
-		"user-manifests-bootstrapper/user-manifests-bootstrapper-pod.yaml",
-	)
 	for _, file := range c.userManifestFiles {
 		data, err := c.substituteParams(c.params, file)
 		if err != nil {
-			panic(err.Error())
+			return nil, err
 		}
 		name := path.Base(file)
 		params := map[string]string{
@@ -154,9 +172,9 @@ func (c *clusterManifestContext) userManifestsBootstrapper() {
 		}
 		manifest, err := c.substituteParams(params, "user-manifests-bootstrapper/user-manifest-template.yaml")
 		if err != nil {
-			panic(err.Error())
+			return nil, err
 		}
-		c.addManifest("user-manifest-"+name, manifest)
+		files = append(files, AssetFile{Name: "user-manifest-" + name, Data: []byte(manifest)})
 	}
 
 	for name, data := range c.userManifests {
@@ -166,10 +184,12 @@ func (c *clusterManifestContext) userManifestsBootstrapper() {
 		}
 		manifest, err := c.substituteParams(params, "user-manifests-bootstrapper/user-manifest-template.yaml")
 		if err != nil {
-			panic(err.Error())
+			return nil, err
 		}
-		c.addManifest("user-manifest-"+name, manifest)
+		files = append(files, AssetFile{Name: "user-manifest-" + name, Data: []byte(manifest)})
 	}
+
+	return files, nil
 }
 
 func (c *clusterManifestContext) addUserManifestFiles(name ...string) {
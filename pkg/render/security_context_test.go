@@ -0,0 +1,72 @@
+package render
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestDeployment(name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetName(name)
+	containers := []interface{}{
+		map[string]interface{}{"name": "main"},
+	}
+	if err := unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", "containers"); err != nil {
+		panic(err)
+	}
+	return obj
+}
+
+// TestSecurityContextTransformerCoversEveryRestrictedSCCComponent guards
+// against the bug where a component drops its SCC RoleBinding under
+// restrictedSCC (see assets_manifests.go and tunnel.go's "if !restrictedSCC"
+// guards) without a matching entry in securityContextComponents, which would
+// leave its Pod spec privileged and fail SCC admission.
+func TestSecurityContextTransformerCoversEveryRestrictedSCCComponent(t *testing.T) {
+	for _, name := range []string{"openvpn-server", "wireguard-server", "router-proxy", "hypershift-operator", "user-manifests-bootstrapper"} {
+		t.Run(name, func(t *testing.T) {
+			obj := newTestDeployment(name)
+			transformed, err := SecurityContextTransformer(true).Transform([]*unstructured.Unstructured{obj})
+			if err != nil {
+				t.Fatalf("Transform returned error: %v", err)
+			}
+			containers, _, err := unstructured.NestedSlice(transformed[0].Object, "spec", "template", "spec", "containers")
+			if err != nil {
+				t.Fatalf("reading containers: %v", err)
+			}
+			container, ok := containers[0].(map[string]interface{})
+			if !ok {
+				t.Fatalf("container is %T, want map[string]interface{}", containers[0])
+			}
+			if _, found := container["securityContext"]; !found {
+				t.Errorf("%s did not receive a securityContext under restrictedSCC", name)
+			}
+		})
+	}
+}
+
+func TestSecurityContextTransformerIgnoresUnknownComponents(t *testing.T) {
+	obj := newTestDeployment("some-unrelated-deployment")
+	transformed, err := SecurityContextTransformer(true).Transform([]*unstructured.Unstructured{obj})
+	if err != nil {
+		t.Fatalf("Transform returned error: %v", err)
+	}
+	containers, _, _ := unstructured.NestedSlice(transformed[0].Object, "spec", "template", "spec", "containers")
+	container := containers[0].(map[string]interface{})
+	if _, found := container["securityContext"]; found {
+		t.Error("an unrelated Deployment unexpectedly received a securityContext")
+	}
+}
+
+func TestSecurityContextForFuncUnrestrictedIsPrivileged(t *testing.T) {
+	securityContext, err := securityContextForFunc(false)("openvpn-server")
+	if err != nil {
+		t.Fatalf("securityContextForFunc(false) returned error: %v", err)
+	}
+	if privileged, _ := securityContext["privileged"].(bool); !privileged {
+		t.Errorf("securityContext[privileged] = %v, want true", securityContext["privileged"])
+	}
+}
@@ -0,0 +1,157 @@
+// Package server exposes rendered hosted control plane manifests over HTTP,
+// so a management hub can render manifests on demand for remote clusters
+// that pull their own config instead of sharing a filesystem outputDir.
+package server
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/openshift-hive/hypershift-installer/pkg/render"
+)
+
+// Source produces the manifests and kubeconfig for a named cluster. Callers
+// typically implement this against whatever already tracks rendered output,
+// e.g. a cache keyed by the arguments passed to render.RenderClusterManifests.
+type Source interface {
+	// ManifestFiles returns the rendered manifest files for cluster, or an
+	// error if the cluster is unknown.
+	ManifestFiles(cluster string) ([]render.AssetFile, error)
+	// Kubeconfig returns the kubeconfig for cluster, or an error if the
+	// cluster is unknown.
+	Kubeconfig(cluster string) ([]byte, error)
+}
+
+// Server serves a Source's output over HTTP, gated by a static bearer token.
+type Server struct {
+	Source Source
+	// Token is the bearer token required of every request. An empty Token
+	// disables authentication, which is only appropriate behind another
+	// authenticating proxy.
+	Token string
+}
+
+// New returns a Server backed by source, requiring token as a bearer token
+// on every request.
+func New(source Source, token string) *Server {
+	return &Server{Source: source, Token: token}
+}
+
+// Handler returns the http.Handler implementing the manifests.tar.gz,
+// manifests/{filename} and kubeconfig endpoints under /clusters/{name}/.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/clusters/", s.authenticate(s.serveCluster))
+	return mux
+}
+
+func (s *Server) authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.logRequest(r)
+		if s.Token != "" {
+			const prefix = "Bearer "
+			auth := r.Header.Get("Authorization")
+			token := strings.TrimPrefix(auth, prefix)
+			if !strings.HasPrefix(auth, prefix) || subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) logRequest(r *http.Request) {
+	client := r.RemoteAddr
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		client = forwarded
+	}
+	log.Printf("manifests server: %s %s from %s", r.Method, r.URL.Path, client)
+}
+
+// serveCluster routes /clusters/{name}/... requests to the manifests.tar.gz,
+// manifests/{filename} or kubeconfig handler.
+func (s *Server) serveCluster(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/clusters/")
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	cluster, resource := parts[0], parts[1]
+
+	switch {
+	case resource == "manifests.tar.gz":
+		s.serveManifestsArchive(w, r, cluster)
+	case resource == "kubeconfig":
+		s.serveKubeconfig(w, r, cluster)
+	case strings.HasPrefix(resource, "manifests/"):
+		s.serveManifestFile(w, r, cluster, strings.TrimPrefix(resource, "manifests/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveManifestsArchive(w http.ResponseWriter, r *http.Request, cluster string) {
+	files, err := s.Source.ManifestFiles(cluster)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s-manifests.tar.gz", cluster))
+
+	gz := gzip.NewWriter(w)
+	defer gz.Close()
+	tw := tar.NewWriter(gz)
+	defer tw.Close()
+
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name: f.Name,
+			Mode: 0644,
+			Size: int64(len(f.Data)),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			log.Printf("manifests server: writing archive header for %s: %v", f.Name, err)
+			return
+		}
+		if _, err := tw.Write(f.Data); err != nil {
+			log.Printf("manifests server: writing archive data for %s: %v", f.Name, err)
+			return
+		}
+	}
+}
+
+func (s *Server) serveManifestFile(w http.ResponseWriter, r *http.Request, cluster, filename string) {
+	files, err := s.Source.ManifestFiles(cluster)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	for _, f := range files {
+		if path.Base(f.Name) == filename {
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write(f.Data)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func (s *Server) serveKubeconfig(w http.ResponseWriter, r *http.Request, cluster string) {
+	kubeconfig, err := s.Source.Kubeconfig(cluster)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(kubeconfig)
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift-hive/hypershift-installer/pkg/render"
+)
+
+// fakeSource is a Source backed by in-memory fixtures, keyed by cluster name.
+type fakeSource struct {
+	files      map[string][]render.AssetFile
+	kubeconfig map[string][]byte
+}
+
+func (s *fakeSource) ManifestFiles(cluster string) ([]render.AssetFile, error) {
+	files, ok := s.files[cluster]
+	if !ok {
+		return nil, fmt.Errorf("unknown cluster %q", cluster)
+	}
+	return files, nil
+}
+
+func (s *fakeSource) Kubeconfig(cluster string) ([]byte, error) {
+	kubeconfig, ok := s.kubeconfig[cluster]
+	if !ok {
+		return nil, fmt.Errorf("unknown cluster %q", cluster)
+	}
+	return kubeconfig, nil
+}
+
+func newTestServer(token string) (*Server, *httptest.Server) {
+	source := &fakeSource{
+		files: map[string][]render.AssetFile{
+			"example": {{Name: "etcd.yaml", Data: []byte("kind: StatefulSet")}},
+		},
+		kubeconfig: map[string][]byte{
+			"example": []byte("apiVersion: v1"),
+		},
+	}
+	s := New(source, token)
+	return s, httptest.NewServer(s.Handler())
+}
+
+func TestAuthenticateRejectsMissingOrWrongToken(t *testing.T) {
+	_, ts := newTestServer("correct-token")
+	defer ts.Close()
+
+	cases := []struct {
+		name   string
+		header string
+	}{
+		{name: "no header", header: ""},
+		{name: "wrong token", header: "Bearer wrong-token"},
+		{name: "missing bearer prefix", header: "correct-token"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, ts.URL+"/clusters/example/kubeconfig", nil)
+			if err != nil {
+				t.Fatalf("NewRequest: %v", err)
+			}
+			if c.header != "" {
+				req.Header.Set("Authorization", c.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAcceptsCorrectToken(t *testing.T) {
+	_, ts := newTestServer("correct-token")
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/clusters/example/kubeconfig", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer correct-token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestAuthenticateDisabledWhenTokenEmpty(t *testing.T) {
+	_, ts := newTestServer("")
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/clusters/example/kubeconfig")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestServeManifestsArchiveContainsAllFiles(t *testing.T) {
+	_, ts := newTestServer("")
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/clusters/example/manifests.tar.gz")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatalf("tr.Next: %v", err)
+	}
+	if hdr.Name != "etcd.yaml" {
+		t.Errorf("archive entry name = %q, want %q", hdr.Name, "etcd.yaml")
+	}
+	if _, err := tr.Next(); err != io.EOF {
+		t.Errorf("expected a single archive entry, got extra entry (err=%v)", err)
+	}
+}
+
+func TestServeManifestFileUnknownClusterReturnsNotFound(t *testing.T) {
+	_, ts := newTestServer("")
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/clusters/unknown/manifests/etcd.yaml")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
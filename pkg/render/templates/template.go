@@ -0,0 +1,85 @@
+// Package templates implements reusable, inheritable cluster configuration:
+// a ClusterTemplate carries default ClusterParams values and manifest
+// toggles, and is instantiated with a sparse overrides document to produce
+// the ClusterParams for one cluster. This removes the copy-paste otherwise
+// needed to provision many similar hosted clusters.
+package templates
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/imdario/mergo"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift-hive/hypershift-installer/pkg/api"
+)
+
+// ClusterTemplate holds the default ClusterParams and manifest toggles that
+// a cluster is instantiated from.
+type ClusterTemplate struct {
+	// Defaults are the ClusterParams values used whenever an instantiating
+	// overrides document leaves a field unset.
+	Defaults api.ClusterParams `json:"defaults"`
+	// Etcd, Tunnel, ExternalOauth and IncludeRegistry mirror the manifest
+	// toggles accepted by render.RenderClusterManifests, so a template can
+	// pin them without every cluster needing to repeat the same flags.
+	Etcd            *bool  `json:"etcd,omitempty"`
+	Tunnel          string `json:"tunnel,omitempty"`
+	ExternalOauth   *bool  `json:"externalOauth,omitempty"`
+	IncludeRegistry *bool  `json:"includeRegistry,omitempty"`
+}
+
+// LoadTemplate reads a ClusterTemplate from path.
+func LoadTemplate(path string) (*ClusterTemplate, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading cluster template %s: %w", path, err)
+	}
+	var t ClusterTemplate
+	if err := yaml.Unmarshal(raw, &t); err != nil {
+		return nil, fmt.Errorf("parsing cluster template %s: %w", path, err)
+	}
+	return &t, nil
+}
+
+// ClusterToggles mirrors the manifest toggles accepted by
+// render.RenderClusterManifests (etcd, tunnel, externalOauth,
+// includeRegistry), resolved from a ClusterTemplate.
+type ClusterToggles struct {
+	Etcd            bool
+	Tunnel          string
+	ExternalOauth   bool
+	IncludeRegistry bool
+}
+
+// Toggles resolves t's manifest toggle fields, defaulting an unset *bool to
+// false.
+func (t *ClusterTemplate) Toggles() ClusterToggles {
+	toggles := ClusterToggles{Tunnel: t.Tunnel}
+	if t.Etcd != nil {
+		toggles.Etcd = *t.Etcd
+	}
+	if t.ExternalOauth != nil {
+		toggles.ExternalOauth = *t.ExternalOauth
+	}
+	if t.IncludeRegistry != nil {
+		toggles.IncludeRegistry = *t.IncludeRegistry
+	}
+	return toggles
+}
+
+// Instantiate produces the ClusterParams and manifest toggles for one
+// cluster by layering overrides on top of t.Defaults: any field left at its
+// zero value in overrides falls back to the template's default. The
+// returned ClusterToggles always come from the template, since overrides is
+// a ClusterParams and has no toggles of its own to override them with.
+func (t *ClusterTemplate) Instantiate(overrides *api.ClusterParams) (*api.ClusterParams, ClusterToggles, error) {
+	merged := t.Defaults
+	if overrides != nil {
+		if err := mergo.Merge(&merged, overrides, mergo.WithOverride); err != nil {
+			return nil, ClusterToggles{}, fmt.Errorf("merging cluster template overrides: %w", err)
+		}
+	}
+	return &merged, t.Toggles(), nil
+}
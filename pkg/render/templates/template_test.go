@@ -0,0 +1,65 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/openshift-hive/hypershift-installer/pkg/api"
+)
+
+func TestClusterTemplateTogglesDefaultsUnsetBoolsToFalse(t *testing.T) {
+	tmpl := &ClusterTemplate{Tunnel: "wireguard"}
+	want := ClusterToggles{Tunnel: "wireguard"}
+	if got := tmpl.Toggles(); got != want {
+		t.Errorf("Toggles() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClusterTemplateTogglesReadsSetFields(t *testing.T) {
+	tru, fls := true, false
+	tmpl := &ClusterTemplate{
+		Etcd:            &fls,
+		Tunnel:          "konnectivity",
+		ExternalOauth:   &tru,
+		IncludeRegistry: &tru,
+	}
+	want := ClusterToggles{Etcd: false, Tunnel: "konnectivity", ExternalOauth: true, IncludeRegistry: true}
+	if got := tmpl.Toggles(); got != want {
+		t.Errorf("Toggles() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClusterTemplateInstantiateOverridesWinOverDefaults(t *testing.T) {
+	tmpl := &ClusterTemplate{Defaults: api.ClusterParams{ReleaseImage: "default-release"}}
+
+	params, _, err := tmpl.Instantiate(&api.ClusterParams{ReleaseImage: "override-release"})
+	if err != nil {
+		t.Fatalf("Instantiate returned error: %v", err)
+	}
+	if params.ReleaseImage != "override-release" {
+		t.Errorf("ReleaseImage = %q, want %q", params.ReleaseImage, "override-release")
+	}
+}
+
+func TestClusterTemplateInstantiateFallsBackToDefaults(t *testing.T) {
+	tmpl := &ClusterTemplate{Defaults: api.ClusterParams{ReleaseImage: "default-release"}}
+
+	params, _, err := tmpl.Instantiate(&api.ClusterParams{})
+	if err != nil {
+		t.Fatalf("Instantiate returned error: %v", err)
+	}
+	if params.ReleaseImage != "default-release" {
+		t.Errorf("ReleaseImage = %q, want %q", params.ReleaseImage, "default-release")
+	}
+}
+
+func TestClusterTemplateInstantiateReturnsTemplateToggles(t *testing.T) {
+	tmpl := &ClusterTemplate{Tunnel: "wireguard"}
+
+	_, toggles, err := tmpl.Instantiate(&api.ClusterParams{})
+	if err != nil {
+		t.Fatalf("Instantiate returned error: %v", err)
+	}
+	if toggles.Tunnel != "wireguard" {
+		t.Errorf("toggles.Tunnel = %q, want %q", toggles.Tunnel, "wireguard")
+	}
+}
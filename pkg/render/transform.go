@@ -0,0 +1,247 @@
+package render
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// Transformer mutates a stream of unstructured manifests after they have
+// been generated by the asset graph and before they are written to disk,
+// letting callers customize the embedded assets without forking them.
+type Transformer interface {
+	// Name identifies the transformer in error messages.
+	Name() string
+	// Transform returns the modified objs. The built-in transformers modify
+	// the objects of objs in place and return the same slice.
+	Transform(objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, error)
+}
+
+// TransformerFunc adapts a function to the Transformer interface.
+type TransformerFunc struct {
+	TransformerName string
+	Func            func([]*unstructured.Unstructured) ([]*unstructured.Unstructured, error)
+}
+
+func (f TransformerFunc) Name() string { return f.TransformerName }
+
+func (f TransformerFunc) Transform(objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	return f.Func(objs)
+}
+
+// applyTransformers runs objs through each transformer in order, returning
+// the first error encountered wrapped with the offending transformer's name.
+func applyTransformers(objs []*unstructured.Unstructured, transformers []Transformer) ([]*unstructured.Unstructured, error) {
+	for _, t := range transformers {
+		var err error
+		objs, err = t.Transform(objs)
+		if err != nil {
+			return nil, fmt.Errorf("transformer %q failed: %w", t.Name(), err)
+		}
+	}
+	return objs, nil
+}
+
+// runTransformers parses files as Kubernetes objects, runs them through
+// c.transformers in order, and re-serializes the result. It is a no-op when
+// no transformers are configured.
+func (c *clusterManifestContext) runTransformers(files []AssetFile) ([]AssetFile, error) {
+	if len(c.transformers) == 0 {
+		return files, nil
+	}
+
+	objs := make([]*unstructured.Unstructured, len(files))
+	for i, f := range files {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(f.Data, &obj.Object); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", f.Name, err)
+		}
+		objs[i] = obj
+	}
+
+	objs, err := applyTransformers(objs, c.transformers)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]AssetFile, len(objs))
+	for i, obj := range objs {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling %s: %w", files[i].Name, err)
+		}
+		out[i] = AssetFile{Name: files[i].Name, Data: data}
+	}
+	return out, nil
+}
+
+// NamespaceTransformer rewrites the namespace of every namespaced object
+// from From to To, leaving objects in other namespaces untouched.
+func NamespaceTransformer(from, to string) Transformer {
+	return TransformerFunc{
+		TransformerName: "NamespaceTransformer",
+		Func: func(objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+			for _, obj := range objs {
+				if obj.GetNamespace() == from {
+					obj.SetNamespace(to)
+				}
+			}
+			return objs, nil
+		},
+	}
+}
+
+// ImageTransformer rewrites container and init container image references
+// that match a key in images to the replacement image reference.
+func ImageTransformer(images map[string]string) Transformer {
+	return TransformerFunc{
+		TransformerName: "ImageTransformer",
+		Func: func(objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+			for _, obj := range objs {
+				if err := rewriteContainerImages(obj, images); err != nil {
+					return nil, err
+				}
+			}
+			return objs, nil
+		},
+	}
+}
+
+func rewriteContainerImages(obj *unstructured.Unstructured, images map[string]string) error {
+	for _, path := range [][]string{
+		{"spec", "template", "spec", "containers"},
+		{"spec", "template", "spec", "initContainers"},
+	} {
+		containers, found, err := unstructured.NestedSlice(obj.Object, path...)
+		if err != nil || !found {
+			continue
+		}
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			image, _, _ := unstructured.NestedString(container, "image")
+			if replacement, ok := images[image]; ok {
+				container["image"] = replacement
+			}
+		}
+		if err := unstructured.SetNestedSlice(obj.Object, containers, path...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LabelTransformer injects the given labels into every object, without
+// overwriting labels already present.
+func LabelTransformer(labels map[string]string) Transformer {
+	return TransformerFunc{
+		TransformerName: "LabelTransformer",
+		Func: func(objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+			for _, obj := range objs {
+				existing := obj.GetLabels()
+				if existing == nil {
+					existing = map[string]string{}
+				}
+				for k, v := range labels {
+					if _, ok := existing[k]; !ok {
+						existing[k] = v
+					}
+				}
+				obj.SetLabels(existing)
+			}
+			return objs, nil
+		},
+	}
+}
+
+// AnnotationTransformer injects the given annotations into every object,
+// without overwriting annotations already present.
+func AnnotationTransformer(annotations map[string]string) Transformer {
+	return TransformerFunc{
+		TransformerName: "AnnotationTransformer",
+		Func: func(objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+			for _, obj := range objs {
+				existing := obj.GetAnnotations()
+				if existing == nil {
+					existing = map[string]string{}
+				}
+				for k, v := range annotations {
+					if _, ok := existing[k]; !ok {
+						existing[k] = v
+					}
+				}
+				obj.SetAnnotations(existing)
+			}
+			return objs, nil
+		},
+	}
+}
+
+// StrategicMergePatchTransformer merges a patch into every object whose
+// GroupVersionKind, namespace and name match one of patches. The merge is
+// mergeUnstructured's recursive map merge: nested maps are merged key by
+// key, while a list or scalar in the patch replaces the object's value
+// outright. Strategic merge list semantics (merging list items by key) and
+// patch directives such as $patch are not supported.
+func StrategicMergePatchTransformer(patches []ObjectPatch) Transformer {
+	return TransformerFunc{
+		TransformerName: "StrategicMergePatchTransformer",
+		Func: func(objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+			for _, obj := range objs {
+				for _, p := range patches {
+					if !p.matches(obj) {
+						continue
+					}
+					if err := mergeUnstructured(obj.Object, p.Patch); err != nil {
+						return nil, fmt.Errorf("patching %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+					}
+				}
+			}
+			return objs, nil
+		},
+	}
+}
+
+// ObjectPatch is a single merge patch targeted at objects matching
+// Kind (and, if set, APIVersion and Namespace) and Name within the manifest
+// stream. Kind is required so that, e.g., a Service and a ConfigMap that
+// happen to share a component's name don't both receive a patch meant for
+// only one of them.
+type ObjectPatch struct {
+	APIVersion string
+	Kind       string
+	Name       string
+	Namespace  string
+	Patch      map[string]interface{}
+}
+
+func (p ObjectPatch) matches(obj *unstructured.Unstructured) bool {
+	if obj.GetName() != p.Name {
+		return false
+	}
+	if obj.GetKind() != p.Kind {
+		return false
+	}
+	if p.APIVersion != "" && obj.GetAPIVersion() != p.APIVersion {
+		return false
+	}
+	return p.Namespace == "" || obj.GetNamespace() == p.Namespace
+}
+
+func mergeUnstructured(dst, src map[string]interface{}) error {
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]interface{}); ok {
+			if dstMap, ok := dst[k].(map[string]interface{}); ok {
+				if err := mergeUnstructured(dstMap, srcMap); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		dst[k] = v
+	}
+	return nil
+}
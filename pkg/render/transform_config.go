@@ -0,0 +1,68 @@
+package render
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"sigs.k8s.io/yaml"
+)
+
+// TransformerConfig is the on-disk, user-facing form of a Transformer
+// pipeline, loaded via LoadTransformerConfig and referenced from a cluster's
+// render configuration.
+type TransformerConfig struct {
+	// Namespace, if set, rewrites every object in From's namespace to To.
+	Namespace *NamespaceTransformerConfig `json:"namespace,omitempty"`
+	// Images maps an image reference found in a container spec to its
+	// replacement.
+	Images map[string]string `json:"images,omitempty"`
+	// Labels are injected into every object that doesn't already set them.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are injected into every object that doesn't already set
+	// them.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Patches are merge patches applied to matching objects by
+	// StrategicMergePatchTransformer.
+	Patches []ObjectPatch `json:"patches,omitempty"`
+}
+
+// NamespaceTransformerConfig configures NamespaceTransformer.
+type NamespaceTransformerConfig struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// LoadTransformerConfig reads a TransformerConfig from path and builds the
+// corresponding ordered list of Transformers.
+func LoadTransformerConfig(path string) ([]Transformer, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading transformer config %s: %w", path, err)
+	}
+	var cfg TransformerConfig
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing transformer config %s: %w", path, err)
+	}
+	return cfg.Transformers(), nil
+}
+
+// Transformers builds the ordered Transformer pipeline described by cfg.
+func (cfg TransformerConfig) Transformers() []Transformer {
+	var transformers []Transformer
+	if cfg.Namespace != nil {
+		transformers = append(transformers, NamespaceTransformer(cfg.Namespace.From, cfg.Namespace.To))
+	}
+	if len(cfg.Images) > 0 {
+		transformers = append(transformers, ImageTransformer(cfg.Images))
+	}
+	if len(cfg.Labels) > 0 {
+		transformers = append(transformers, LabelTransformer(cfg.Labels))
+	}
+	if len(cfg.Annotations) > 0 {
+		transformers = append(transformers, AnnotationTransformer(cfg.Annotations))
+	}
+	if len(cfg.Patches) > 0 {
+		transformers = append(transformers, StrategicMergePatchTransformer(cfg.Patches))
+	}
+	return transformers
+}
@@ -0,0 +1,88 @@
+package render
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestNamespaceTransformerRewritesOnlyMatchingNamespace(t *testing.T) {
+	objs := []*unstructured.Unstructured{
+		newTestObject("v1", "ConfigMap", "from-ns", "a"),
+		newTestObject("v1", "ConfigMap", "other-ns", "b"),
+	}
+
+	transformed, err := NamespaceTransformer("from-ns", "to-ns").Transform(objs)
+	if err != nil {
+		t.Fatalf("Transform returned error: %v", err)
+	}
+	if got := transformed[0].GetNamespace(); got != "to-ns" {
+		t.Errorf("objs[0].Namespace = %q, want %q", got, "to-ns")
+	}
+	if got := transformed[1].GetNamespace(); got != "other-ns" {
+		t.Errorf("objs[1].Namespace = %q, want unchanged %q", got, "other-ns")
+	}
+}
+
+// TestStrategicMergePatchTransformerMatchesByKind guards against the bug
+// where two objects of different kinds sharing a name would both receive a
+// patch meant for only one of them.
+func TestStrategicMergePatchTransformerMatchesByKind(t *testing.T) {
+	service := newTestObject("v1", "Service", "ns", "etcd")
+	configMap := newTestObject("v1", "ConfigMap", "ns", "etcd")
+
+	patch := ObjectPatch{
+		Kind: "Service",
+		Name: "etcd",
+		Patch: map[string]interface{}{
+			"spec": map[string]interface{}{"clusterIP": "None"},
+		},
+	}
+
+	transform := StrategicMergePatchTransformer([]ObjectPatch{patch})
+	transformed, err := transform.Transform([]*unstructured.Unstructured{service, configMap})
+	if err != nil {
+		t.Fatalf("Transform returned error: %v", err)
+	}
+
+	clusterIP, found, err := unstructured.NestedString(transformed[0].Object, "spec", "clusterIP")
+	if err != nil || !found || clusterIP != "None" {
+		t.Errorf("Service spec.clusterIP = %q (found=%v, err=%v), want \"None\"", clusterIP, found, err)
+	}
+	if _, found, _ := unstructured.NestedString(transformed[1].Object, "spec", "clusterIP"); found {
+		t.Error("ConfigMap received a patch meant for the Service of the same name")
+	}
+}
+
+func TestObjectPatchMatchesRequiresKind(t *testing.T) {
+	patch := ObjectPatch{Kind: "Service", Name: "etcd"}
+	if patch.matches(newTestObject("v1", "ConfigMap", "ns", "etcd")) {
+		t.Error("matches() = true for a ConfigMap against a Service-only patch")
+	}
+	if !patch.matches(newTestObject("v1", "Service", "ns", "etcd")) {
+		t.Error("matches() = false for a Service matching Kind and Name")
+	}
+}
+
+func TestObjectPatchMatchesOptionalAPIVersionAndNamespace(t *testing.T) {
+	patch := ObjectPatch{APIVersion: "apps/v1", Kind: "Deployment", Name: "etcd", Namespace: "ns"}
+
+	if patch.matches(newTestObject("apps/v1beta1", "Deployment", "ns", "etcd")) {
+		t.Error("matches() = true for a mismatched APIVersion")
+	}
+	if patch.matches(newTestObject("apps/v1", "Deployment", "other-ns", "etcd")) {
+		t.Error("matches() = true for a mismatched Namespace")
+	}
+	if !patch.matches(newTestObject("apps/v1", "Deployment", "ns", "etcd")) {
+		t.Error("matches() = false for a fully matching object")
+	}
+}
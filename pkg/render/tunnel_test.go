@@ -0,0 +1,69 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTunnelProviderForName(t *testing.T) {
+	cases := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: OpenVPNTunnelName},
+		{name: OpenVPNTunnelName, want: OpenVPNTunnelName},
+		{name: WireGuardTunnelName, want: WireGuardTunnelName},
+		{name: KonnectivityTunnelName, want: KonnectivityTunnelName},
+		{name: "bogus", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			provider, err := TunnelProviderForName(c.name)
+			if c.wantErr {
+				if err == nil {
+					t.Fatal("TunnelProviderForName returned a nil error for an unknown name")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TunnelProviderForName returned error: %v", err)
+			}
+			if got := provider.Name(); got != c.want {
+				t.Errorf("Name() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestTunnelProviderFuncMapsRegisterIncludeVPN(t *testing.T) {
+	providers := []TunnelProvider{OpenVPNProvider{}, WireGuardProvider{}, KonnectivityProvider{}}
+	for _, p := range providers {
+		t.Run(p.Name(), func(t *testing.T) {
+			if _, ok := p.FuncMap(nil)["includeVPN"]; !ok {
+				t.Errorf("%s.FuncMap() is missing includeVPN", p.Name())
+			}
+		})
+	}
+}
+
+// TestKubeAPIServerTemplatesOnlyOpenVPNNeedsClientConfig guards against the
+// kube-apiserver-vpnclient-config.yaml ConfigMap rendering unconditionally
+// for tunnel providers that don't use it.
+func TestKubeAPIServerTemplatesOnlyOpenVPNNeedsClientConfig(t *testing.T) {
+	cases := []struct {
+		provider TunnelProvider
+		want     []string
+	}{
+		{provider: OpenVPNProvider{}, want: []string{"kube-apiserver/kube-apiserver-vpnclient-config.yaml"}},
+		{provider: WireGuardProvider{}, want: nil},
+		{provider: KonnectivityProvider{}, want: nil},
+	}
+	for _, c := range cases {
+		t.Run(c.provider.Name(), func(t *testing.T) {
+			if got := c.provider.KubeAPIServerTemplates(); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("KubeAPIServerTemplates() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}